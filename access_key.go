@@ -0,0 +1,107 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"math/big"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// ed25519KeyType is the NEAR wire encoding for an Ed25519 public key in a
+// PublicKey/AccessKey action, as opposed to Secp256k1.
+const ed25519KeyType = 0
+
+// fullAccessPermission and functionCallPermission are the two variants of
+// AccessKeyPermission's borsh enum.
+const (
+	functionCallPermission = 0
+	fullAccessPermission   = 1
+)
+
+// AddFullAccessKey grants publicKey full access to this account, letting it
+// sign any transaction on the account's behalf.
+func (a *Account) AddFullAccessKey(publicKey ed25519.PublicKey) (map[string]interface{}, error) {
+	return a.SignAndSendTransaction(a.AccountID, []Action{{
+		Enum: 5,
+		AddKey: AddKey{
+			PublicKey: PublicKey{KeyType: ed25519KeyType, Data: publicKey},
+			AccessKey: AccessKey{
+				Permission: AccessKeyPermission{Enum: fullAccessPermission},
+			},
+		},
+	}})
+}
+
+// AddFunctionCallAccessKey grants publicKey a restricted access key that can
+// only call the given methodNames on receiverID, spending at most allowance
+// yoctoNEAR on gas. A nil methodNames allows any method; a nil allowance
+// grants unlimited gas spend.
+func (a *Account) AddFunctionCallAccessKey(
+	publicKey ed25519.PublicKey,
+	receiverID string,
+	methodNames []string,
+	allowance *big.Int,
+) (map[string]interface{}, error) {
+	return a.SignAndSendTransaction(a.AccountID, []Action{{
+		Enum: 5,
+		AddKey: AddKey{
+			PublicKey: PublicKey{KeyType: ed25519KeyType, Data: publicKey},
+			AccessKey: AccessKey{
+				Permission: AccessKeyPermission{
+					Enum: functionCallPermission,
+					FunctionCall: FunctionCallPermission{
+						Allowance:   allowance,
+						ReceiverID:  receiverID,
+						MethodNames: methodNames,
+					},
+				},
+			},
+		},
+	}})
+}
+
+// DeleteKey removes publicKey from this account's set of access keys.
+func (a *Account) DeleteKey(publicKey ed25519.PublicKey) (map[string]interface{}, error) {
+	return a.SignAndSendTransaction(a.AccountID, []Action{{
+		Enum: 6,
+		DeleteKey: DeleteKey{
+			PublicKey: PublicKey{KeyType: ed25519KeyType, Data: publicKey},
+		},
+	}})
+}
+
+// RotateKeys adds newKey as a full access key and removes the account's
+// current key, as two actions in a single signed transaction, so the
+// account is never left without a valid key between the two operations. On
+// success, a itself is updated to sign with newKey, since its old key was
+// just deleted on-chain and can no longer authorize anything.
+func (a *Account) RotateKeys(newKey ed25519.PrivateKey) (map[string]interface{}, error) {
+	newPubKey := newKey.Public().(ed25519.PublicKey)
+	result, err := a.SignAndSendTransaction(a.AccountID, []Action{
+		{
+			Enum: 5,
+			AddKey: AddKey{
+				PublicKey: PublicKey{KeyType: ed25519KeyType, Data: newPubKey},
+				AccessKey: AccessKey{
+					Permission: AccessKeyPermission{Enum: fullAccessPermission},
+				},
+			},
+		},
+		{
+			Enum: 6,
+			DeleteKey: DeleteKey{
+				PublicKey: PublicKey{KeyType: ed25519KeyType, Data: a.pubKey},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.pubKey = newPubKey
+	a.privKey = newKey
+	a.PublicKey = ed25519Prefix + base58.Encode(newPubKey)
+	a.wallet = nil
+	a.accessKeyByPublicKeyCache = make(map[string]map[string]interface{})
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package near
+
+import "testing"
+
+func TestAccessKeyPermitsActions(t *testing.T) {
+	fullAccess := map[string]interface{}{"permission": "FullAccess"}
+	scoped := map[string]interface{}{
+		"permission": map[string]interface{}{
+			"FunctionCall": map[string]interface{}{
+				"receiver_id":  "contractA",
+				"method_names": []interface{}{"foo"},
+			},
+		},
+	}
+
+	transfer := []Action{{Enum: 3}}
+	fooOnA := []Action{{Enum: 2, FunctionCall: FunctionCall{MethodName: "foo"}}}
+	fooOnB := []Action{{Enum: 2, FunctionCall: FunctionCall{MethodName: "foo"}}}
+	barOnA := []Action{{Enum: 2, FunctionCall: FunctionCall{MethodName: "bar"}}}
+
+	if !accessKeyPermitsActions(fullAccess, "anything", transfer) {
+		t.Errorf("FullAccess key should permit any action on any receiver")
+	}
+
+	if !accessKeyPermitsActions(scoped, "contractA", fooOnA) {
+		t.Errorf("scoped key should permit its configured method on its configured receiver")
+	}
+
+	if accessKeyPermitsActions(scoped, "contractB", fooOnB) {
+		t.Errorf("scoped key must not permit its configured method on a different receiver")
+	}
+
+	if accessKeyPermitsActions(scoped, "contractA", barOnA) {
+		t.Errorf("scoped key must not permit a method outside its allowlist")
+	}
+
+	if accessKeyPermitsActions(scoped, "contractA", transfer) {
+		t.Errorf("scoped key must not permit a non-FunctionCall action")
+	}
+}
+
+func TestAccessKeyPermitsActionsFailsClosed(t *testing.T) {
+	missingPermission := map[string]interface{}{}
+	unrecognizedPermission := map[string]interface{}{"permission": 42}
+
+	if accessKeyPermitsActions(missingPermission, "contractA", []Action{{Enum: 3}}) {
+		t.Errorf("an access key response with no permission field must deny, not allow, everything")
+	}
+	if accessKeyPermitsActions(unrecognizedPermission, "contractA", []Action{{Enum: 3}}) {
+		t.Errorf("an unrecognized permission shape must deny, not allow, everything")
+	}
+}
@@ -12,11 +12,16 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/aurora-is-near/near-api-go/keystore"
 	"github.com/aurora-is-near/near-api-go/utils"
 	"github.com/btcsuite/btcutil/base58"
 	"github.com/near/borsh-go"
 )
 
+// keystoreSuffix distinguishes encrypted credential files from the
+// plaintext ones read by LoadAccount.
+const keystoreSuffix = ".keystore.json"
+
 const ed25519Prefix = "ed25519:"
 
 // Default number of retries with different nonce before giving up on a transaction.
@@ -36,9 +41,18 @@ type Account struct {
 	conn                      *Connection
 	pubKey                    ed25519.PublicKey
 	privKey                   ed25519.PrivateKey
+	wallet                    Wallet
+	walletPath                DerivationPath
 	accessKeyByPublicKeyCache map[string]map[string]interface{}
 }
 
+// Wallet returns the Wallet backing this account's signing, or nil if the
+// account was loaded directly from a plaintext or encrypted credential file
+// rather than through a Manager.
+func (a *Account) Wallet() Wallet {
+	return a.wallet
+}
+
 // LoadAccount loads the credential for the receiverID account, to be used via
 // connection c, and returns it.
 func LoadAccount(c *Connection, cfg *Config, receiverID string) (*Account, error) {
@@ -95,6 +109,60 @@ func (a *Account) readAccessKey(filename, receiverID string) error {
 	return nil
 }
 
+// LoadAccountEncrypted loads the credential for the receiverID account from
+// an encrypted keystore file (see package keystore) instead of the plaintext
+// JSON read by LoadAccount, and returns it. The plaintext private key is
+// never written to disk.
+func LoadAccountEncrypted(c *Connection, cfg *Config, receiverID, passphrase string) (*Account, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	fn := filepath.Join(home, ".near-credentials", cfg.NetworkID, receiverID+keystoreSuffix)
+	buf, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(buf, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if key.AccountID != receiverID {
+		return nil, fmt.Errorf("near: parsed account_id '%s' does not match with receiverID '%s'",
+			key.AccountID, receiverID)
+	}
+
+	var a Account
+	a.conn = c
+	a.AccountID = key.AccountID
+	a.pubKey = key.PublicKey
+	a.privKey = key.PrivateKey
+	a.PublicKey = ed25519Prefix + base58.Encode(key.PublicKey)
+	a.accessKeyByPublicKeyCache = make(map[string]map[string]interface{})
+	return &a, nil
+}
+
+// SaveEncrypted writes an encrypted keystore file for the account into dir,
+// protected by passphrase, so that plaintext credentials produced by
+// LoadAccount can be migrated to the safer on-disk format.
+func (a *Account) SaveEncrypted(dir, passphrase string) error {
+	if a.privKey == nil {
+		return fmt.Errorf("near: account %s has no private key to encrypt (it is backed by a Wallet, not a local key)",
+			a.AccountID)
+	}
+	key := &keystore.Key{
+		AccountID:  a.AccountID,
+		PublicKey:  a.pubKey,
+		PrivateKey: a.privKey,
+	}
+	buf, err := keystore.EncryptKey(key, passphrase, keystore.StandardScryptN, keystore.StandardScryptP)
+	if err != nil {
+		return err
+	}
+	fn := filepath.Join(dir, a.AccountID+keystoreSuffix)
+	return ioutil.WriteFile(fn, buf, 0600)
+}
+
 // SendMoney sends amount NEAR from account to receiverID.
 func (a *Account) SendMoney(
 	receiverID string,
@@ -146,7 +214,7 @@ func (a *Account) signTransaction(
 	receiverID string,
 	actions []Action,
 ) (txHash []byte, signedTx *SignedTransaction, err error) {
-	_, ak, err := a.findAccessKey()
+	_, ak, err := a.findAccessKey(receiverID, actions)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -172,27 +240,112 @@ func (a *Account) signTransaction(
 	// save nonce
 	ak["nonce"] = json.Number(strconv.FormatInt(nonce, 10))
 
-	// sign transaction
+	// sign transaction, delegating to the hardware wallet if this account
+	// is backed by one rather than an in-process private key
+	if a.wallet != nil {
+		return signTransactionWithSigner(receiverID, uint64(nonce), actions, base58.Decode(blockHash),
+			a.pubKey, a.AccountID, func(hash []byte) ([]byte, error) {
+				return a.wallet.SignHash(a.walletPath, hash)
+			})
+	}
 	return signTransaction(receiverID, uint64(nonce), actions, base58.Decode(blockHash),
 		a.pubKey, a.privKey, a.AccountID)
 
 }
 
-func (a *Account) findAccessKey() (publicKey ed25519.PublicKey, accessKey map[string]interface{}, err error) {
-	// TODO: Find matching access key based on transaction
+// findAccessKey looks up (and caches) the access key a signs with, and
+// makes sure it actually has permission to perform actions: a cached
+// FunctionCall-only key must not be used to authorize a Transfer, AddKey,
+// or any other action it wasn't scoped for.
+func (a *Account) findAccessKey(receiverID string, actions []Action) (publicKey ed25519.PublicKey, accessKey map[string]interface{}, err error) {
 	// TODO: use accountId and networkId?
 	pk := a.pubKey
-	if ak := a.accessKeyByPublicKeyCache[string(publicKey)]; ak != nil {
-		return pk, ak, nil
+	ak := a.accessKeyByPublicKeyCache[string(pk)]
+	if ak == nil {
+		ak, err = a.conn.ViewAccessKey(a.AccountID, a.PublicKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		a.accessKeyByPublicKeyCache[string(pk)] = ak
 	}
-	ak, err := a.conn.ViewAccessKey(a.AccountID, a.PublicKey)
-	if err != nil {
-		return nil, nil, err
+	if !accessKeyPermitsActions(ak, receiverID, actions) {
+		return nil, nil, fmt.Errorf("near: access key %s does not have permission to perform the requested actions on %s",
+			a.PublicKey, receiverID)
 	}
-	a.accessKeyByPublicKeyCache[string(publicKey)] = ak
 	return pk, ak, nil
 }
 
+// accessKeyPermitsActions reports whether ak, as returned by
+// Connection.ViewAccessKey, grants enough permission to perform actions
+// against receiverID. A FullAccess key permits anything; a FunctionCall-
+// scoped key only permits FunctionCall actions aimed at its configured
+// receiver, using one of its configured method names. An empty receiver_id
+// permits any receiver, and an empty method_names list permits any method.
+func accessKeyPermitsActions(ak map[string]interface{}, receiverID string, actions []Action) bool {
+	permission, ok := ak["permission"]
+	if !ok {
+		// An access key response we don't recognize must never be
+		// treated as granting permission.
+		return false
+	}
+	if permission == "FullAccess" {
+		return true
+	}
+	permissionMap, ok := permission.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	scope, ok := permissionMap["FunctionCall"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	scopedReceiverID, _ := scope["receiver_id"].(string)
+	allowedMethods, _ := scope["method_names"].([]interface{})
+
+	if scopedReceiverID != "" && scopedReceiverID != receiverID {
+		return false
+	}
+
+	for _, action := range actions {
+		if action.Enum != 2 { // FunctionCall
+			return false
+		}
+		if len(allowedMethods) == 0 {
+			continue
+		}
+		permitted := false
+		for _, m := range allowedMethods {
+			if name, ok := m.(string); ok && name == action.FunctionCall.MethodName {
+				permitted = true
+				break
+			}
+		}
+		if !permitted {
+			return false
+		}
+	}
+	return true
+}
+
+// EstimateGas dry-runs a FunctionCall to contractID.methodName with args and
+// returns the gas it actually burns, so callers can populate the gas
+// parameter of FunctionCall instead of guessing. deposit is accepted for
+// API symmetry with FunctionCall, but the underlying view-context
+// simulation (see SimulatedBackend.DryRun) cannot execute deposit-gated
+// code paths, so estimates for methods whose gas usage depends on the
+// attached deposit may be inaccurate.
+func (a *Account) EstimateGas(
+	contractID, methodName string,
+	args []byte,
+	deposit big.Int,
+) (uint64, error) {
+	outcome, err := NewSimulatedBackend(a.conn).DryRun(contractID, methodName, args)
+	if err != nil {
+		return 0, err
+	}
+	return outcome.GasBurnt, nil
+}
+
 // FunctionCall performs a NEAR function call.
 func (a *Account) FunctionCall(
 	contractID, methodName string,
@@ -0,0 +1,177 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aurora-is-near/near-api-go/keystore"
+)
+
+// keystoreScanInterval is how often KeystoreBackend re-reads its directory
+// looking for keystore files that were added or removed.
+const keystoreScanInterval = 2 * time.Second
+
+// KeystoreBackend watches a directory of encrypted keystore files (see
+// package keystore) and exposes each one as a Wallet, decrypting it as soon
+// as a directory scan discovers it. It is modeled on go-ethereum's
+// accounts/keystore file cache.
+type KeystoreBackend struct {
+	dir        string
+	passphrase string
+
+	mu      sync.Mutex
+	cache   map[string]time.Time // filename -> mtime
+	wallets map[string]*keystoreWallet
+	subs    []chan<- WalletEvent
+	done    chan struct{}
+}
+
+// NewKeystoreBackend creates a KeystoreBackend that decrypts keystore files
+// under dir with passphrase as they are discovered.
+func NewKeystoreBackend(dir, passphrase string) *KeystoreBackend {
+	b := &KeystoreBackend{
+		dir:        dir,
+		passphrase: passphrase,
+		cache:      make(map[string]time.Time),
+		wallets:    make(map[string]*keystoreWallet),
+		done:       make(chan struct{}),
+	}
+	b.scan()
+	go b.loop()
+	return b
+}
+
+// Close stops the background directory scan.
+func (b *KeystoreBackend) Close() error {
+	close(b.done)
+	return nil
+}
+
+// Wallets implements Backend.
+func (b *KeystoreBackend) Wallets() []Wallet {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wallets := make([]Wallet, 0, len(b.wallets))
+	for _, w := range b.wallets {
+		wallets = append(wallets, w)
+	}
+	return wallets
+}
+
+// Subscribe implements Backend.
+func (b *KeystoreBackend) Subscribe(sink chan<- WalletEvent) Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subs = append(b.subs, sink)
+	return &keystoreSub{backend: b, sink: sink}
+}
+
+func (b *KeystoreBackend) loop() {
+	ticker := time.NewTicker(keystoreScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.scan()
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// scan implements the file_cache pattern: list the directory, diff against
+// the last known state, and fire WalletArrived/WalletDropped for whatever
+// changed.
+func (b *KeystoreBackend) scan() {
+	entries, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return
+	}
+
+	seen := make(map[string]time.Time, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), keystoreSuffix) {
+			continue
+		}
+		seen[e.Name()] = e.ModTime()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for name, mtime := range seen {
+		if old, ok := b.cache[name]; ok && old.Equal(mtime) {
+			continue
+		}
+		buf, err := ioutil.ReadFile(filepath.Join(b.dir, name))
+		if err != nil {
+			continue
+		}
+		key, err := keystore.DecryptKey(buf, b.passphrase)
+		if err != nil {
+			continue
+		}
+		w := &keystoreWallet{accountID: key.AccountID, pubKey: key.PublicKey, privKey: key.PrivateKey}
+		b.wallets[name] = w
+		b.fire(WalletEvent{Wallet: w, Kind: WalletArrived})
+	}
+	for name, w := range b.wallets {
+		if _, ok := seen[name]; !ok {
+			delete(b.wallets, name)
+			b.fire(WalletEvent{Wallet: w, Kind: WalletDropped})
+		}
+	}
+	b.cache = seen
+}
+
+func (b *KeystoreBackend) fire(ev WalletEvent) {
+	for _, sink := range b.subs {
+		select {
+		case sink <- ev:
+		default:
+		}
+	}
+}
+
+type keystoreSub struct {
+	backend *KeystoreBackend
+	sink    chan<- WalletEvent
+}
+
+func (s *keystoreSub) Unsubscribe() {
+	s.backend.mu.Lock()
+	defer s.backend.mu.Unlock()
+	for i, sink := range s.backend.subs {
+		if sink == s.sink {
+			s.backend.subs = append(s.backend.subs[:i], s.backend.subs[i+1:]...)
+			break
+		}
+	}
+}
+
+// keystoreWallet is the Wallet produced by a KeystoreBackend. The key
+// material is already decrypted in memory; this is the in-process
+// equivalent of a hardware wallet's Derive/SignHash.
+type keystoreWallet struct {
+	accountID string
+	pubKey    ed25519.PublicKey
+	privKey   ed25519.PrivateKey
+}
+
+func (w *keystoreWallet) AccountID() string { return w.accountID }
+
+func (w *keystoreWallet) Status() string { return "ok" }
+
+func (w *keystoreWallet) Derive(DerivationPath) (ed25519.PublicKey, error) {
+	return w.pubKey, nil
+}
+
+func (w *keystoreWallet) SignHash(_ DerivationPath, hash []byte) ([]byte, error) {
+	return ed25519.Sign(w.privKey, hash), nil
+}
@@ -0,0 +1,69 @@
+package near
+
+import "crypto/ed25519"
+
+// InMemoryBackend is a Backend holding a fixed set of wallets constructed
+// directly from ed25519 keypairs. It is intended for tests, where spinning
+// up an encrypted keystore directory would be unnecessary ceremony.
+type InMemoryBackend struct {
+	wallets []Wallet
+}
+
+// MemoryAccount is one account to seed an InMemoryBackend with.
+type MemoryAccount struct {
+	AccountID  string
+	PrivateKey ed25519.PrivateKey
+}
+
+// NewInMemoryBackend creates an InMemoryBackend with one wallet per
+// account, each resolvable by its AccountID through Manager.Find.
+func NewInMemoryBackend(accounts ...MemoryAccount) *InMemoryBackend {
+	b := &InMemoryBackend{}
+	for _, acc := range accounts {
+		b.AddAccount(acc.AccountID, acc.PrivateKey)
+	}
+	return b
+}
+
+// AddAccount registers a wallet for accountID backed by privKey.
+func (b *InMemoryBackend) AddAccount(accountID string, privKey ed25519.PrivateKey) {
+	b.wallets = append(b.wallets, &memoryWallet{
+		accountID: accountID,
+		pubKey:    privKey.Public().(ed25519.PublicKey),
+		privKey:   privKey,
+	})
+}
+
+// Wallets implements Backend.
+func (b *InMemoryBackend) Wallets() []Wallet {
+	return append([]Wallet(nil), b.wallets...)
+}
+
+// Subscribe implements Backend. An InMemoryBackend never reports events,
+// including for wallets added later with AddAccount, so the returned
+// Subscription delivers nothing.
+func (b *InMemoryBackend) Subscribe(chan<- WalletEvent) Subscription {
+	return noopSubscription{}
+}
+
+type noopSubscription struct{}
+
+func (noopSubscription) Unsubscribe() {}
+
+type memoryWallet struct {
+	accountID string
+	pubKey    ed25519.PublicKey
+	privKey   ed25519.PrivateKey
+}
+
+func (w *memoryWallet) AccountID() string { return w.accountID }
+
+func (w *memoryWallet) Status() string { return "ok" }
+
+func (w *memoryWallet) Derive(DerivationPath) (ed25519.PublicKey, error) {
+	return w.pubKey, nil
+}
+
+func (w *memoryWallet) SignHash(_ DerivationPath, hash []byte) ([]byte, error) {
+	return ed25519.Sign(w.privKey, hash), nil
+}
@@ -0,0 +1,28 @@
+// Package keystore implements encrypted storage of NEAR account keys on
+// disk, modeled on Ethereum's Web3 Secret Storage v3 format.
+package keystore
+
+import (
+	"crypto/ed25519"
+)
+
+// Key wraps an ed25519 keypair together with the NEAR account it belongs
+// to, in the decrypted, in-memory form.
+type Key struct {
+	AccountID  string
+	PublicKey  ed25519.PublicKey
+	PrivateKey ed25519.PrivateKey
+}
+
+// Scrypt parameters. Standard trades off decryption time for resistance to
+// brute force; Light is weaker but keeps interactive use responsive.
+const (
+	StandardScryptN = 1 << 18
+	StandardScryptP = 1
+
+	LightScryptN = 1 << 12
+	LightScryptP = 6
+
+	scryptR     = 8
+	scryptDKLen = 32
+)
@@ -0,0 +1,188 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const keystoreVersion = 3
+
+// encryptedKeyJSON is the on-disk representation of an encrypted Key,
+// mirroring Ethereum's Web3 Secret Storage v3 layout.
+type encryptedKeyJSON struct {
+	AccountID string     `json:"account_id"`
+	PublicKey string     `json:"public_key"`
+	Crypto    cryptoJSON `json:"crypto"`
+	Version   int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string       `json:"cipher"`
+	CipherText   string       `json:"ciphertext"`
+	CipherParams cipherParams `json:"cipherparams"`
+	KDF          string       `json:"kdf"`
+	KDFParams    kdfParams    `json:"kdfparams"`
+	MAC          string       `json:"mac"`
+}
+
+type cipherParams struct {
+	IV string `json:"iv"`
+}
+
+type kdfParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// EncryptKey encrypts key with passphrase and returns its Web3 Secret
+// Storage v3 JSON encoding. scryptN and scryptP control the cost of the
+// scrypt KDF; see StandardScryptN/StandardScryptP and their Light
+// counterparts for reasonable defaults.
+func EncryptKey(key *Key, passphrase string, scryptN, scryptP int) ([]byte, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	encryptKey := derivedKey[:16]
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTRXOR(encryptKey, key.PrivateKey, iv)
+	if err != nil {
+		return nil, err
+	}
+
+	// MAC = SHA-256(derivedKey[16:32] || ciphertext). Ethereum's format
+	// uses Keccak256 here; NEAR has no native Keccak dependency, so we use
+	// SHA-256 instead and document the deviation.
+	mac := sha256.Sum256(append(derivedKey[16:32], cipherText...))
+
+	encJSON := encryptedKeyJSON{
+		AccountID: key.AccountID,
+		PublicKey: hex.EncodeToString(key.PublicKey),
+		Crypto: cryptoJSON{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: kdfParams{
+				N:     scryptN,
+				R:     scryptR,
+				P:     scryptP,
+				DKLen: scryptDKLen,
+				Salt:  hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(mac[:]),
+		},
+		Version: keystoreVersion,
+	}
+	return json.Marshal(encJSON)
+}
+
+// DecryptKey decrypts a key encoded with EncryptKey using passphrase.
+func DecryptKey(keyJSON []byte, passphrase string) (*Key, error) {
+	var encJSON encryptedKeyJSON
+	if err := json.Unmarshal(keyJSON, &encJSON); err != nil {
+		return nil, err
+	}
+	if encJSON.Version != keystoreVersion {
+		return nil, fmt.Errorf("keystore: unsupported version %d", encJSON.Version)
+	}
+	if encJSON.Crypto.Cipher != "aes-128-ctr" {
+		return nil, fmt.Errorf("keystore: unsupported cipher %q", encJSON.Crypto.Cipher)
+	}
+	if encJSON.Crypto.KDF != "scrypt" {
+		return nil, fmt.Errorf("keystore: unsupported kdf %q", encJSON.Crypto.KDF)
+	}
+
+	salt, err := hex.DecodeString(encJSON.Crypto.KDFParams.Salt)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(encJSON.Crypto.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	iv, err := hex.DecodeString(encJSON.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, err
+	}
+
+	p := encJSON.Crypto.KDFParams
+	// scrypt.Key validates N, r, and p but not the derived key length: a
+	// negative dklen panics deep inside pbkdf2.Key, and a dklen below the
+	// 32 bytes we slice out below (16 for the AES key, 16 more for the MAC
+	// input) panics at that slice, rather than returning an error. Reject it ourselves first, since
+	// this JSON comes straight from an untrusted on-disk file.
+	if p.DKLen < 32 {
+		return nil, fmt.Errorf("keystore: invalid derived key length %d in kdfparams", p.DKLen)
+	}
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, p.N, p.R, p.P, p.DKLen)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := sha256.Sum256(append(derivedKey[16:32], cipherText...))
+	wantMAC, err := hex.DecodeString(encJSON.Crypto.MAC)
+	if err != nil {
+		return nil, err
+	}
+	if !hmacEqual(mac[:], wantMAC) {
+		return nil, fmt.Errorf("keystore: could not decrypt key with given passphrase")
+	}
+
+	privKey, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, err
+	}
+	pubKey, err := hex.DecodeString(encJSON.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{
+		AccountID:  encJSON.AccountID,
+		PublicKey:  ed25519.PublicKey(pubKey),
+		PrivateKey: ed25519.PrivateKey(privKey),
+	}, nil
+}
+
+func aesCTRXOR(key, inText, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	stream := cipher.NewCTR(block, iv)
+	outText := make([]byte, len(inText))
+	stream.XORKeyStream(outText, inText)
+	return outText, nil
+}
+
+// hmacEqual does a constant-time comparison of two MACs.
+func hmacEqual(a, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	var v byte
+	for i := range a {
+		v |= a[i] ^ b[i]
+	}
+	return v == 0
+}
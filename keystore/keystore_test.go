@@ -0,0 +1,80 @@
+package keystore
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+)
+
+func TestEncryptDecryptKeyRoundTrip(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &Key{AccountID: "alice.near", PublicKey: pub, PrivateKey: priv}
+
+	encJSON, err := EncryptKey(key, "correct horse battery staple", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey: %v", err)
+	}
+
+	got, err := DecryptKey(encJSON, "correct horse battery staple")
+	if err != nil {
+		t.Fatalf("DecryptKey: %v", err)
+	}
+
+	if got.AccountID != key.AccountID {
+		t.Errorf("AccountID = %q, want %q", got.AccountID, key.AccountID)
+	}
+	if !bytes.Equal(got.PublicKey, key.PublicKey) {
+		t.Errorf("PublicKey mismatch")
+	}
+	if !bytes.Equal(got.PrivateKey, key.PrivateKey) {
+		t.Errorf("PrivateKey mismatch")
+	}
+}
+
+func TestDecryptKeyWrongPassphrase(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &Key{AccountID: "alice.near", PublicKey: pub, PrivateKey: priv}
+
+	encJSON, err := EncryptKey(key, "correct horse battery staple", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey: %v", err)
+	}
+
+	if _, err := DecryptKey(encJSON, "wrong passphrase"); err == nil {
+		t.Error("expected an error decrypting with the wrong passphrase")
+	}
+}
+
+// TestDecryptKeyMalformedDKLen guards against a corrupted or truncated
+// keystore file crashing the process (e.g. inside KeystoreBackend's
+// background directory poll) instead of returning an error.
+func TestDecryptKeyMalformedDKLen(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &Key{AccountID: "alice.near", PublicKey: pub, PrivateKey: priv}
+
+	encJSON, err := EncryptKey(key, "correct horse battery staple", LightScryptN, LightScryptP)
+	if err != nil {
+		t.Fatalf("EncryptKey: %v", err)
+	}
+
+	for _, dklen := range []int{0, -1, -16, 31} {
+		corrupted := bytes.Replace(encJSON, []byte(`"dklen":32`), []byte(fmt.Sprintf(`"dklen":%d`, dklen)), 1)
+		if bytes.Equal(corrupted, encJSON) {
+			t.Fatalf("test fixture did not contain the expected dklen field to corrupt")
+		}
+		if _, err := DecryptKey(corrupted, "correct horse battery staple"); err == nil {
+			t.Errorf("dklen=%d: expected an error, got none", dklen)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/karalabe/hid"
+	"github.com/near/borsh-go"
+)
+
+// usbScanInterval is how often USBHub re-enumerates USB HID devices
+// looking for Ledgers that were plugged in or unplugged.
+const usbScanInterval = 1 * time.Second
+
+// NEAR Ledger app APDU constants.
+const (
+	ledgerCLA          = 0x80
+	ledgerInsGetPubKey = 0x04
+	ledgerInsSign      = 0x02
+
+	ledgerVendorID = 0x2c97 // Ledger SA
+)
+
+// hardenedBit marks a BIP-32 derivation index as hardened.
+const hardenedBit = 0x80000000
+
+// LedgerWallet is a Wallet backed by a NEAR Ledger app, reached over USB
+// HID. The ed25519 private key never leaves the device: Derive asks it for
+// a public key, and SignHash asks it to sign a precomputed hash.
+type LedgerWallet struct {
+	info hid.DeviceInfo
+
+	mu     sync.Mutex
+	device *hid.Device
+}
+
+// newLedgerWallet wraps a HID device already identified as a NEAR Ledger
+// app by USBHub.
+func newLedgerWallet(info hid.DeviceInfo) *LedgerWallet {
+	return &LedgerWallet{info: info}
+}
+
+// AccountID implements Wallet. LedgerWallet cannot know which NEAR account a
+// derived key is associated with; callers identify accounts by derivation
+// path instead, so this always returns the empty string.
+func (w *LedgerWallet) AccountID() string { return "" }
+
+// Status implements Wallet.
+func (w *LedgerWallet) Status() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.device == nil {
+		return "disconnected"
+	}
+	return "ok"
+}
+
+func (w *LedgerWallet) open() (*hid.Device, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.device != nil {
+		return w.device, nil
+	}
+	dev, err := w.info.Open()
+	if err != nil {
+		return nil, fmt.Errorf("near: failed to open ledger device: %w", err)
+	}
+	w.device = dev
+	return dev, nil
+}
+
+// Derive implements Wallet, returning the public key at path.
+func (w *LedgerWallet) Derive(path DerivationPath) (ed25519.PublicKey, error) {
+	resp, err := w.exchange(ledgerInsGetPubKey, encodeDerivationPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) < ed25519.PublicKeySize {
+		return nil, fmt.Errorf("near: short public key response from ledger (%d bytes)", len(resp))
+	}
+	return ed25519.PublicKey(resp[:ed25519.PublicKeySize]), nil
+}
+
+// SignHash implements Wallet, asking the device to sign hash (typically
+// sha256 of a borsh-serialized transaction) with the key at path.
+func (w *LedgerWallet) SignHash(path DerivationPath, hash []byte) ([]byte, error) {
+	payload := append(encodeDerivationPath(path), hash...)
+	resp, err := w.exchange(ledgerInsSign, payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("near: unexpected signature length from ledger (%d bytes)", len(resp))
+	}
+	return resp, nil
+}
+
+// exchange sends a single APDU (CLA ledgerCLA, the given instruction, P1=P2=0)
+// and returns its response data.
+func (w *LedgerWallet) exchange(ins byte, data []byte) ([]byte, error) {
+	dev, err := w.open()
+	if err != nil {
+		return nil, err
+	}
+	apdu := make([]byte, 5+len(data))
+	apdu[0] = ledgerCLA
+	apdu[1] = ins
+	apdu[4] = byte(len(data))
+	copy(apdu[5:], data)
+
+	if _, err := dev.Write(apdu); err != nil {
+		return nil, fmt.Errorf("near: ledger write failed: %w", err)
+	}
+	resp := make([]byte, 256)
+	n, err := dev.Read(resp)
+	if err != nil {
+		return nil, fmt.Errorf("near: ledger read failed: %w", err)
+	}
+	return resp[:n], nil
+}
+
+// encodeDerivationPath serializes path as big-endian uint32s, with the
+// hardened bit set on every index, matching the NEAR Ledger app's
+// convention of hardened-only derivation.
+func encodeDerivationPath(path DerivationPath) []byte {
+	buf := make([]byte, 1+4*len(path))
+	buf[0] = byte(len(path))
+	for i, idx := range path {
+		binary.BigEndian.PutUint32(buf[1+4*i:], idx|hardenedBit)
+	}
+	return buf
+}
+
+// signTransactionWithSigner builds and signs a transaction exactly like the
+// free-standing signTransaction helper, except it obtains the signature by
+// calling sign(hash) instead of ed25519.Sign(privKey, hash) directly. This
+// is how Account.signTransaction delegates to a hardware wallet (or any
+// other Wallet) that never exposes its private key to the process.
+func signTransactionWithSigner(
+	receiverID string,
+	nonce uint64,
+	actions []Action,
+	blockHash []byte,
+	pubKey ed25519.PublicKey,
+	accountID string,
+	sign func(hash []byte) ([]byte, error),
+) (txHash []byte, signedTx *SignedTransaction, err error) {
+	tx := Transaction{
+		SignerID:   accountID,
+		PublicKey:  PublicKey{KeyType: ed25519KeyType, Data: pubKey},
+		Nonce:      nonce,
+		ReceiverID: receiverID,
+		BlockHash:  blockHash,
+		Actions:    actions,
+	}
+
+	buf, err := borsh.Serialize(tx)
+	if err != nil {
+		return nil, nil, err
+	}
+	hash := sha256.Sum256(buf)
+
+	sig, err := sign(hash[:])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signedTx = &SignedTransaction{
+		Transaction: tx,
+		Signature: Signature{
+			KeyType: ed25519KeyType,
+			Data:    sig,
+		},
+	}
+	return hash[:], signedTx, nil
+}
+
+// LedgerAccount binds a NEAR account to a specific derivation path on a
+// Ledger device, the hardware-wallet equivalent of MemoryAccount.
+type LedgerAccount struct {
+	AccountID string
+	Path      DerivationPath
+}
+
+// boundLedgerWallet is the Wallet USBHub hands out for a LedgerAccount: it
+// reports the configured AccountID and always signs at the configured
+// Path, ignoring whatever path Account.walletPath happens to carry, so a
+// single physical device can back several NEAR accounts at once.
+type boundLedgerWallet struct {
+	device    *LedgerWallet
+	deviceKey string
+	accountID string
+	path      DerivationPath
+}
+
+func (w *boundLedgerWallet) AccountID() string { return w.accountID }
+
+func (w *boundLedgerWallet) Status() string { return w.device.Status() }
+
+func (w *boundLedgerWallet) Derive(DerivationPath) (ed25519.PublicKey, error) {
+	return w.device.Derive(w.path)
+}
+
+func (w *boundLedgerWallet) SignHash(_ DerivationPath, hash []byte) ([]byte, error) {
+	return w.device.SignHash(w.path, hash)
+}
+
+// USBHub scans for connected NEAR Ledger devices and tracks them as they
+// are plugged and unplugged, exposing each configured LedgerAccount as a
+// Wallet resolvable by its real NEAR account id, the way Manager.Find and
+// Manager.Accounts expect. It is modeled on go-ethereum's
+// accounts/usbwallet hub.
+type USBHub struct {
+	accounts []LedgerAccount
+
+	mu      sync.Mutex
+	devices map[string]*LedgerWallet      // device key -> raw device
+	wallets map[string]*boundLedgerWallet // device key + "|" + account id -> bound wallet
+	subs    []chan<- WalletEvent
+	done    chan struct{}
+}
+
+// NewUSBHub creates a USBHub that exposes accounts as they're found on any
+// connected Ledger, performs an initial device scan, and starts a
+// background poll so plug/unplug events keep firing without the caller
+// having to invoke Refresh itself.
+func NewUSBHub(accounts ...LedgerAccount) *USBHub {
+	h := &USBHub{
+		accounts: accounts,
+		devices:  make(map[string]*LedgerWallet),
+		wallets:  make(map[string]*boundLedgerWallet),
+		done:     make(chan struct{}),
+	}
+	h.Refresh()
+	go h.loop()
+	return h
+}
+
+// Close stops the background device poll.
+func (h *USBHub) Close() error {
+	close(h.done)
+	return nil
+}
+
+func (h *USBHub) loop() {
+	ticker := time.NewTicker(usbScanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.Refresh()
+		case <-h.done:
+			return
+		}
+	}
+}
+
+// Refresh re-enumerates USB HID devices and fires WalletArrived/
+// WalletDropped for whatever changed since the last scan. Every configured
+// LedgerAccount is exposed on every connected device, since there is no
+// way to know in advance which physical Ledger holds which NEAR account.
+func (h *USBHub) Refresh() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	seenDevices := make(map[string]bool)
+	for _, info := range hid.Enumerate(ledgerVendorID, 0) {
+		deviceKey := info.Path
+		seenDevices[deviceKey] = true
+
+		dev, ok := h.devices[deviceKey]
+		if !ok {
+			dev = newLedgerWallet(info)
+			h.devices[deviceKey] = dev
+		}
+
+		for _, acc := range h.accounts {
+			walletKey := deviceKey + "|" + acc.AccountID
+			if _, ok := h.wallets[walletKey]; ok {
+				continue
+			}
+			w := &boundLedgerWallet{device: dev, deviceKey: deviceKey, accountID: acc.AccountID, path: acc.Path}
+			h.wallets[walletKey] = w
+			h.fire(WalletEvent{Wallet: w, Kind: WalletArrived})
+		}
+	}
+
+	for walletKey, w := range h.wallets {
+		if !seenDevices[w.deviceKey] {
+			delete(h.wallets, walletKey)
+			h.fire(WalletEvent{Wallet: w, Kind: WalletDropped})
+		}
+	}
+	for deviceKey := range h.devices {
+		if !seenDevices[deviceKey] {
+			delete(h.devices, deviceKey)
+		}
+	}
+}
+
+func (h *USBHub) fire(ev WalletEvent) {
+	for _, sink := range h.subs {
+		select {
+		case sink <- ev:
+		default:
+		}
+	}
+}
+
+// Wallets implements Backend.
+func (h *USBHub) Wallets() []Wallet {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	wallets := make([]Wallet, 0, len(h.wallets))
+	for _, w := range h.wallets {
+		wallets = append(wallets, w)
+	}
+	return wallets
+}
+
+// Subscribe implements Backend.
+func (h *USBHub) Subscribe(sink chan<- WalletEvent) Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subs = append(h.subs, sink)
+	return &usbHubSub{hub: h, sink: sink}
+}
+
+type usbHubSub struct {
+	hub  *USBHub
+	sink chan<- WalletEvent
+}
+
+func (s *usbHubSub) Unsubscribe() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	for i, sink := range s.hub.subs {
+		if sink == s.sink {
+			s.hub.subs = append(s.hub.subs[:i], s.hub.subs[i+1:]...)
+			break
+		}
+	}
+}
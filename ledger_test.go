@@ -0,0 +1,50 @@
+package near
+
+import (
+	"testing"
+
+	"github.com/karalabe/hid"
+)
+
+func TestEncodeDerivationPath(t *testing.T) {
+	got := encodeDerivationPath(DerivationPath{44, 397, 0, 0, 0})
+	if got[0] != 5 {
+		t.Fatalf("expected length prefix 5, got %d", got[0])
+	}
+	if len(got) != 1+4*5 {
+		t.Fatalf("expected %d bytes, got %d", 1+4*5, len(got))
+	}
+	for i, want := range []uint32{44, 397, 0, 0, 0} {
+		got32 := uint32(got[1+4*i])<<24 | uint32(got[2+4*i])<<16 | uint32(got[3+4*i])<<8 | uint32(got[4+4*i])
+		if got32 != want|hardenedBit {
+			t.Errorf("index %d: got %#x, want %#x", i, got32, want|hardenedBit)
+		}
+	}
+}
+
+func TestEncodeDerivationPathEmpty(t *testing.T) {
+	got := encodeDerivationPath(nil)
+	if len(got) != 1 || got[0] != 0 {
+		t.Fatalf("expected a single zero-length byte, got %v", got)
+	}
+}
+
+// TestBoundLedgerWalletAccountID checks that a boundLedgerWallet reports the
+// NEAR account id it was registered under, unlike the raw LedgerWallet it
+// wraps, which has no way to know which account a device holds.
+func TestBoundLedgerWalletAccountID(t *testing.T) {
+	dev := newLedgerWallet(hid.DeviceInfo{Path: "fake"})
+	if got := dev.AccountID(); got != "" {
+		t.Fatalf("raw LedgerWallet.AccountID() = %q, want empty", got)
+	}
+
+	bound := &boundLedgerWallet{
+		device:    dev,
+		deviceKey: "fake",
+		accountID: "alice.near",
+		path:      DerivationPath{44, 397, 0, 0, 0},
+	}
+	if got := bound.AccountID(); got != "alice.near" {
+		t.Errorf("boundLedgerWallet.AccountID() = %q, want alice.near", got)
+	}
+}
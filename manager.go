@@ -0,0 +1,202 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+// DerivationPath is a BIP-32 style derivation path, expressed as a sequence
+// of child indices. An index with the hardened bit (0x80000000) set derives
+// a hardened child. Software wallets that hold a single key ignore it.
+type DerivationPath []uint32
+
+// Wallet is a backend capable of signing on behalf of a single NEAR
+// account. Implementations may keep the private key in memory (software
+// wallets) or delegate signing to an external device that never exposes
+// the key to the process (hardware wallets).
+type Wallet interface {
+	// AccountID returns the NEAR account this wallet signs for.
+	AccountID() string
+
+	// Status returns a human readable description of the wallet's
+	// connection state, e.g. "ok" or "disconnected".
+	Status() string
+
+	// Derive returns the public key reachable at path.
+	Derive(path DerivationPath) (ed25519.PublicKey, error)
+
+	// SignHash signs hash, typically the sha256 digest of a
+	// borsh-serialized transaction, using the key at path.
+	SignHash(path DerivationPath, hash []byte) ([]byte, error)
+}
+
+// WalletKind identifies whether a WalletEvent reports a wallet becoming
+// available or going away.
+type WalletKind int
+
+const (
+	// WalletArrived is fired when a backend detects a new wallet.
+	WalletArrived WalletKind = iota
+	// WalletDropped is fired when a previously known wallet disappears.
+	WalletDropped
+)
+
+// WalletEvent is emitted by a Manager whenever a backend reports a wallet
+// arriving or being removed.
+type WalletEvent struct {
+	Wallet Wallet
+	Kind   WalletKind
+}
+
+// Backend is a source of Wallets, such as a keystore directory or a USB
+// hub scanning for hardware devices.
+type Backend interface {
+	// Wallets returns the wallets currently known to the backend.
+	Wallets() []Wallet
+
+	// Subscribe creates an event subscription that is sent wallet
+	// arrival and departure notifications until it is unsubscribed.
+	Subscribe(sink chan<- WalletEvent) Subscription
+}
+
+// Subscription represents a stream of events delivered to a channel passed
+// to Backend.Subscribe or Manager.Subscribe. Call Unsubscribe to terminate
+// it.
+type Subscription interface {
+	Unsubscribe()
+}
+
+// Manager is the overarching account manager that dispatches transaction
+// signing to whichever backend holds the requested account's key.
+type Manager struct {
+	conn     *Connection
+	backends []Backend
+
+	mu   sync.Mutex
+	subs []*managerSub
+}
+
+// NewManager creates a Manager backed by the given backends, used via
+// connection c to submit transactions.
+func NewManager(c *Connection, backends ...Backend) *Manager {
+	return &Manager{conn: c, backends: backends}
+}
+
+// Wallets returns every wallet known to all configured backends.
+func (m *Manager) Wallets() []Wallet {
+	var wallets []Wallet
+	for _, b := range m.backends {
+		wallets = append(wallets, b.Wallets()...)
+	}
+	return wallets
+}
+
+// Accounts returns an Account for every wallet known to the manager. A
+// wallet whose public key cannot be derived is left out.
+func (m *Manager) Accounts() []Account {
+	var accounts []Account
+	for _, w := range m.Wallets() {
+		a, err := m.account(w)
+		if err != nil {
+			continue
+		}
+		accounts = append(accounts, *a)
+	}
+	return accounts
+}
+
+// Find returns the Account backed by the wallet for accountID.
+func (m *Manager) Find(accountID string) (*Account, error) {
+	for _, w := range m.Wallets() {
+		if w.AccountID() == accountID {
+			return m.account(w)
+		}
+	}
+	return nil, fmt.Errorf("near: no wallet found for account %q", accountID)
+}
+
+func (m *Manager) account(w Wallet) (*Account, error) {
+	pubKey, err := w.Derive(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		AccountID:                 w.AccountID(),
+		PublicKey:                 ed25519Prefix + base58.Encode(pubKey),
+		conn:                      m.conn,
+		pubKey:                    pubKey,
+		wallet:                    w,
+		accessKeyByPublicKeyCache: make(map[string]map[string]interface{}),
+	}, nil
+}
+
+// NewAccountWithWallet builds an Account that signs via wallet at the given
+// derivation path, used via connection c. It is the entry point for wallets
+// that were not registered with a Manager (so there is no Backend to pick a
+// path on the caller's behalf) and for wallets, such as a Ledger holding
+// several NEAR accounts, where the default (nil) path is not the one that
+// should sign.
+func NewAccountWithWallet(c *Connection, accountID string, wallet Wallet, path DerivationPath) (*Account, error) {
+	pubKey, err := wallet.Derive(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		AccountID:                 accountID,
+		PublicKey:                 ed25519Prefix + base58.Encode(pubKey),
+		conn:                      c,
+		pubKey:                    pubKey,
+		wallet:                    wallet,
+		walletPath:                path,
+		accessKeyByPublicKeyCache: make(map[string]map[string]interface{}),
+	}, nil
+}
+
+// SignTx signs the given actions on behalf of accountID without submitting
+// them, delegating to that account's wallet.
+func (m *Manager) SignTx(accountID, receiverID string, actions []Action) (*SignedTransaction, error) {
+	a, err := m.Find(accountID)
+	if err != nil {
+		return nil, err
+	}
+	_, signedTx, err := a.signTransaction(receiverID, actions)
+	return signedTx, err
+}
+
+// Subscribe forwards wallet arrival/removal events from every backend to
+// sink until the returned Subscription is unsubscribed.
+func (m *Manager) Subscribe(sink chan<- WalletEvent) Subscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub := &managerSub{manager: m, sink: sink}
+	for _, b := range m.backends {
+		sub.upstream = append(sub.upstream, b.Subscribe(sink))
+	}
+	m.subs = append(m.subs, sub)
+	return sub
+}
+
+type managerSub struct {
+	manager  *Manager
+	sink     chan<- WalletEvent
+	upstream []Subscription
+}
+
+func (s *managerSub) Unsubscribe() {
+	for _, u := range s.upstream {
+		u.Unsubscribe()
+	}
+
+	s.manager.mu.Lock()
+	defer s.manager.mu.Unlock()
+	for i, sub := range s.manager.subs {
+		if sub == s {
+			s.manager.subs = append(s.manager.subs[:i], s.manager.subs[i+1:]...)
+			break
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package near
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+)
+
+func TestManagerFindResolvesByAccountID(t *testing.T) {
+	_, privA, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, privB, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := NewInMemoryBackend(
+		MemoryAccount{AccountID: "alice.near", PrivateKey: privA},
+		MemoryAccount{AccountID: "bob.near", PrivateKey: privB},
+	)
+	m := NewManager(nil, backend)
+
+	a, err := m.Find("alice.near")
+	if err != nil {
+		t.Fatalf("Find(alice.near): %v", err)
+	}
+	if a.AccountID != "alice.near" {
+		t.Errorf("got account %q, want alice.near", a.AccountID)
+	}
+
+	if _, err := m.Find("carol.near"); err == nil {
+		t.Errorf("expected an error finding an unknown account")
+	}
+
+	accounts := m.Accounts()
+	if len(accounts) != 2 {
+		t.Errorf("got %d accounts, want 2", len(accounts))
+	}
+}
+
+// fixedWallet is a Wallet stub that always derives to the same key,
+// regardless of the path it's asked for, so tests can assert on what path
+// was actually requested.
+type fixedWallet struct {
+	accountID  string
+	pubKey     ed25519.PublicKey
+	lastDerive DerivationPath
+}
+
+func (w *fixedWallet) AccountID() string { return w.accountID }
+func (w *fixedWallet) Status() string    { return "ok" }
+func (w *fixedWallet) Derive(path DerivationPath) (ed25519.PublicKey, error) {
+	w.lastDerive = path
+	return w.pubKey, nil
+}
+func (w *fixedWallet) SignHash(DerivationPath, []byte) ([]byte, error) { return nil, nil }
+
+func TestNewAccountWithWalletSetsPath(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &fixedWallet{accountID: "alice.near", pubKey: pub}
+	path := DerivationPath{44, 397, 1, 0, 0}
+
+	a, err := NewAccountWithWallet(nil, "alice.near", w, path)
+	if err != nil {
+		t.Fatalf("NewAccountWithWallet: %v", err)
+	}
+	if a.AccountID != "alice.near" {
+		t.Errorf("AccountID = %q, want alice.near", a.AccountID)
+	}
+	if a.walletPath == nil || len(a.walletPath) != len(path) {
+		t.Fatalf("walletPath not stored on the account: got %v", a.walletPath)
+	}
+	for i, idx := range path {
+		if a.walletPath[i] != idx {
+			t.Errorf("walletPath[%d] = %d, want %d", i, a.walletPath[i], idx)
+		}
+	}
+	if len(w.lastDerive) != len(path) {
+		t.Errorf("Derive was not called with the requested path: got %v", w.lastDerive)
+	}
+}
@@ -0,0 +1,110 @@
+package near
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/near/borsh-go"
+)
+
+// BuildTransaction constructs an unsigned Transaction from a to receiverID
+// using the given nonce and blockHash, rather than looking them up over the
+// network as SignAndSendTransaction does. Pair with SignTransactionOffline
+// and Connection.BroadcastRawTransaction to sign on an air-gapped machine
+// and broadcast from a separate, online one.
+func (a *Account) BuildTransaction(
+	receiverID string,
+	actions []Action,
+	nonce uint64,
+	blockHash []byte,
+) (*Transaction, error) {
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("near: transaction must have at least one action")
+	}
+	return &Transaction{
+		SignerID:   a.AccountID,
+		PublicKey:  PublicKey{KeyType: ed25519KeyType, Data: a.pubKey},
+		Nonce:      nonce,
+		ReceiverID: receiverID,
+		BlockHash:  blockHash,
+		Actions:    actions,
+	}, nil
+}
+
+// SignTransactionOffline signs tx with this account's key, or its hardware
+// wallet if one is attached, without any network access, and returns the
+// signed transaction together with its borsh-serialized bytes, ready to be
+// handed to Connection.BroadcastRawTransaction.
+func (a *Account) SignTransactionOffline(tx *Transaction) (*SignedTransaction, []byte, error) {
+	var (
+		signedTx *SignedTransaction
+		err      error
+	)
+	if a.wallet != nil {
+		_, signedTx, err = signTransactionWithSigner(tx.ReceiverID, tx.Nonce, tx.Actions, tx.BlockHash,
+			a.pubKey, a.AccountID, func(hash []byte) ([]byte, error) {
+				return a.wallet.SignHash(a.walletPath, hash)
+			})
+	} else {
+		_, signedTx, err = signTransaction(tx.ReceiverID, tx.Nonce, tx.Actions, tx.BlockHash,
+			a.pubKey, a.privKey, a.AccountID)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buf, err := borsh.Serialize(*signedTx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return signedTx, buf, nil
+}
+
+// NextNonce returns the nonce that should be used for the next transaction
+// signed by publicKey on behalf of accountID, i.e. one greater than that
+// access key's current nonce.
+func (c *Connection) NextNonce(accountID, publicKey string) (uint64, error) {
+	ak, err := c.ViewAccessKey(accountID, publicKey)
+	if err != nil {
+		return 0, err
+	}
+	jsonNonce, ok := ak["nonce"].(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("near: access key for %s has no nonce", accountID)
+	}
+	nonce, err := jsonNonce.Int64()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(nonce) + 1, nil
+}
+
+// LatestBlockHash returns the hash of the most recent block, for use as the
+// blockHash argument to Account.BuildTransaction.
+func (c *Connection) LatestBlockHash() ([]byte, error) {
+	block, err := c.Block()
+	if err != nil {
+		return nil, err
+	}
+	hash, ok := block["header"].(map[string]interface{})["hash"].(string)
+	if !ok {
+		return nil, fmt.Errorf("near: block response missing header.hash")
+	}
+	return base58.Decode(hash), nil
+}
+
+// BroadcastRawTransaction submits a borsh-serialized SignedTransaction
+// produced offline by Account.SignTransactionOffline to the network. If
+// wait is true it blocks until the transaction is included, matching
+// SendTransaction; otherwise it returns as soon as the node has accepted it
+// into its mempool.
+func (c *Connection) BroadcastRawTransaction(signedTxBytes []byte, wait bool) (map[string]interface{}, error) {
+	if wait {
+		return c.SendTransaction(signedTxBytes)
+	}
+	return c.call("broadcast_tx_async", []interface{}{
+		base64.StdEncoding.EncodeToString(signedTxBytes),
+	})
+}
@@ -0,0 +1,94 @@
+package near
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// SimulatedBackend executes function calls and estimates gas through an
+// RPC node's view-call facility, without ever submitting a signed
+// transaction. It is the read-only counterpart to
+// Account.SignAndSendTransaction.
+type SimulatedBackend struct {
+	conn *Connection
+}
+
+// NewSimulatedBackend creates a SimulatedBackend using connection c.
+func NewSimulatedBackend(c *Connection) *SimulatedBackend {
+	return &SimulatedBackend{conn: c}
+}
+
+// CallFunction performs a read-only view call of methodName on contractID
+// with args, returning its return value, any logs it printed, and the gas
+// it burnt.
+func (s *SimulatedBackend) CallFunction(
+	contractID, methodName string,
+	args []byte,
+) (result []byte, logs []string, gasBurnt uint64, err error) {
+	resp, err := s.conn.call("query", map[string]interface{}{
+		"request_type": "call_function",
+		"finality":     "final",
+		"account_id":   contractID,
+		"method_name":  methodName,
+		"args_base64":  base64.StdEncoding.EncodeToString(args),
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	rawResult, _ := resp["result"].([]interface{})
+	result = make([]byte, len(rawResult))
+	for i, v := range rawResult {
+		n, ok := v.(json.Number)
+		if !ok {
+			return nil, nil, 0, fmt.Errorf("near: unexpected result byte %v", v)
+		}
+		b, err := n.Int64()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		result[i] = byte(b)
+	}
+
+	if rawLogs, ok := resp["logs"].([]interface{}); ok {
+		for _, l := range rawLogs {
+			if line, ok := l.(string); ok {
+				logs = append(logs, line)
+			}
+		}
+	}
+
+	if n, ok := resp["gas_burnt"].(json.Number); ok {
+		burnt, err := n.Int64()
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		gasBurnt = uint64(burnt)
+	}
+	return result, logs, gasBurnt, nil
+}
+
+// DryRun estimates the outcome of calling methodName on contractID with
+// args, including the gas it would burn, without ever submitting a signed
+// transaction.
+//
+// An earlier version of this method tried to replay an unsubmitted
+// SignedTransaction through EXPERIMENTAL_tx_status, but that RPC only
+// accepts the hash of a transaction already included on-chain and cannot
+// simulate one that hasn't been sent. There is no NEAR RPC that replays an
+// arbitrary unsubmitted transaction, so instead DryRun reuses CallFunction:
+// the node executes the method in a sandboxed view context, nothing it
+// does is persisted, and it reports the gas actually burnt, which is
+// exactly what Account.EstimateGas needs.
+func (s *SimulatedBackend) DryRun(contractID, methodName string, args []byte) (*TransactionOutcome, error) {
+	result, logs, gasBurnt, err := s.CallFunction(contractID, methodName, args)
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionOutcome{
+		Result:   result,
+		Logs:     logs,
+		GasBurnt: gasBurnt,
+	}, nil
+}